@@ -16,6 +16,11 @@
 //  2. Any bad submissions will be left in the learndir. Manually inspect these and where possible, replace all the Learn files for a submission with a single file called "uun.pdf" (where uun is the student's UUN, e.g. s1234567).
 //  3. Re-run the above command. This will process the "uun.pdf" files.
 //
+// For each student, sources are tried in priority order until one produces a
+// submission: the Learn export, then a manually-dropped "uun.pdf", then (if
+// configured) an MS Forms CSV export, then (if configured) an email inbox.
+// See pkg/source for details.
+//
 
 package main
 
@@ -27,14 +32,45 @@ import (
 	"time"
 	"flag"
 	"encoding/csv"
-	"log"
 	"io"
-	"regexp"
 	"strconv"
 
 	"github.com/timdrysdale/parselearn"
+
+	"github.com/timdrysdale/gradex-ingest/pkg/archive"
+	"github.com/timdrysdale/gradex-ingest/pkg/assemble"
+	"github.com/timdrysdale/gradex-ingest/pkg/audit"
+	"github.com/timdrysdale/gradex-ingest/pkg/manifest"
+	"github.com/timdrysdale/gradex-ingest/pkg/source"
 )
 
+// archiveRecord logs the outcome of extracting a zipped/tarred submission.
+// parselearn.Submission has no column for this, so it is reported in its own
+// CSV alongside the usual success/error reports.
+type archiveRecord struct {
+	ExamNumber     string
+	UUN            string
+	ArchiveType    string
+	SourceFilename string
+}
+
+// assemblyRecord logs which files were merged into a multi-file submission,
+// so markers can audit the page ordering.
+type assemblyRecord struct {
+	ExamNumber string
+	UUN        string
+	Sources    string // semicolon-separated, in assembled order
+}
+
+// baseNames returns the base filename of each path, in order.
+func baseNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, p := range paths {
+		names[i] = filepath.Base(p)
+	}
+	return names
+}
+
 
 func main() {
 
@@ -42,78 +78,199 @@ func main() {
 
     var courseCode string
     flag.StringVar(&courseCode, "course", "MATH00000", "the course code, will be prepended to output file names")
-	
+
 	var classList string
     flag.StringVar(&classList, "classlist", "MATH00000_enrolment.csv", "csv file containing the student UUN, Exam Number and number of minutes of extra time they are entitled to")
-	
+
 	var learnDir string
     flag.StringVar(&learnDir, "learndir", "learn_dir", "path of the folder containing the unzipped Learn download")
-	
+
 	var outputDir string
     flag.StringVar(&outputDir, "outputdir", "output_dir", "path of the folder where output files should go")
-	
+
 	var deadline string
     flag.StringVar(&deadline, "deadline", "2020-04-22-16-00", "date and time of the normal submission deadline")
-	
+
+	var msFormsCSV string
+	flag.StringVar(&msFormsCSV, "msformscsv", "", "optional: csv export of an MS Forms file-upload question, for students who submitted that way")
+
+	var imapServer string
+	flag.StringVar(&imapServer, "imapserver", "", "optional: host:port of an IMAP inbox to check for emailed submissions")
+
+	var imapUser string
+	flag.StringVar(&imapUser, "imapuser", "", "username for -imapserver")
+
+	var imapPassword string
+	flag.StringVar(&imapPassword, "imappassword", "", "password for -imapserver")
+
+	var imapMailbox string
+	flag.StringVar(&imapMailbox, "imapmailbox", "INBOX", "mailbox to search on -imapserver")
+
+	var dryRun bool
+	flag.BoolVar(&dryRun, "dryrun", false, "log every move/remove that would happen, without touching disk")
+
 	flag.Parse()
 
-	deadline_time, e := time.Parse("2006-01-02-15-04", deadline)
-	check(e)
-	
-	fmt.Println("course: ", courseCode)
-	fmt.Println("deadline: ", deadline_time.Format("2006-01-02 at 15:04"))	
-	fmt.Println("class list csv: ", classList)
-	fmt.Println("learn folder: ", learnDir)
-	fmt.Println("folders to read: ", flag.Args())
-	
-	// Check the output directory exists, and if not then make it
-	err := ensureDir(outputDir)
-	if err != nil {
-		os.MkdirAll(outputDir, os.ModePerm)
+	cfg := Config{
+		Course:       courseCode,
+		ClassList:    classList,
+		LearnDir:     learnDir,
+		OutputDir:    outputDir,
+		Deadline:     deadline,
+		MSFormsCSV:   msFormsCSV,
+		IMAPServer:   imapServer,
+		IMAPUser:     imapUser,
+		IMAPPassword: imapPassword,
+		IMAPMailbox:  imapMailbox,
+		DryRun:       dryRun,
 	}
-	err = ensureDir(outputDir)
-	if err != nil {
+
+	if err := run(cfg, NewIngester(dryRun)); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	
+
+	os.Exit(0)
+}
+
+// Config holds gradex-ingest's command-line configuration. It exists
+// separately from the flag.StringVar calls that populate it in main so that
+// run can also be driven directly from a test.
+type Config struct {
+	Course       string
+	ClassList    string
+	LearnDir     string
+	OutputDir    string
+	Deadline     string
+	MSFormsCSV   string
+	IMAPServer   string
+	IMAPUser     string
+	IMAPPassword string
+	IMAPMailbox  string
+	DryRun       bool
+}
+
+// run performs one ingest: it reads cfg.ClassList, fetches each student's
+// submission from whichever source has it, and writes the anonymised PDFs
+// and CSV reports into cfg.OutputDir. ing does every filesystem mutation, so
+// a test can pass one backed by afero.NewMemMapFs() to exercise this
+// end-to-end without touching the real disk.
+func run(cfg Config, ing *Ingester) error {
+
+	deadline_time, err := time.Parse("2006-01-02-15-04", cfg.Deadline)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("course: ", cfg.Course)
+	fmt.Println("deadline: ", deadline_time.Format("2006-01-02 at 15:04"))
+	fmt.Println("class list csv: ", cfg.ClassList)
+	fmt.Println("learn folder: ", cfg.LearnDir)
+
+	// Check the output directory exists, and if not then make it
+	if err := ing.ensureDir(cfg.OutputDir); err != nil {
+		return err
+	}
+
 	// Read the contents of the Learn folder
-	err = ensureDir(learnDir)
+	if err := ing.ensureDir(cfg.LearnDir); err != nil {
+		return err
+	}
+
+	// Build the list of sources to try for each student, in priority order
+	learnSource, err := source.NewLearnSource(ing.Fs, cfg.LearnDir)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return err
 	}
-		
-	// regex to read the UUN that appears in the Learn files
-	finduun, _ := regexp.Compile("_(s[0-9]{7})_attempt_")
-	
-	// Build map of UUN to filename, for each .txt receipt file in the learnDir
-	var learn_files = map[string]string{}
-	filepath.Walk(learnDir, func(path string, f os.FileInfo, _ error) error {
-		if !f.IsDir() {
-			r, err := regexp.MatchString(".txt", f.Name())
-			if err == nil && r {
-				//fmt.Println(f.Name())
-				extracted_uun := finduun.FindStringSubmatch(f.Name())[1]
-				learn_files[strings.ToUpper(extracted_uun)] = f.Name()
-			}
+	fmt.Println("learn files: ", learnSource.Dir)
+
+	sources := []source.Source{
+		learnSource,
+		&source.ManualPDFSource{Fs: ing.Fs, Dir: cfg.LearnDir},
+	}
+	if cfg.MSFormsCSV != "" {
+		msForms, err := source.NewMSFormsSource(ing.Fs, cfg.MSFormsCSV, filepath.Join(cfg.LearnDir, "msforms"))
+		if err != nil {
+			return err
 		}
-		return nil
-	})
-	fmt.Println("learn files: ",len(learn_files))
-	
-	
-	// Read the class list csv	
-	csvfile, err := os.Open(classList)
+		msForms.DryRun = cfg.DryRun
+		sources = append(sources, msForms)
+	}
+	if cfg.IMAPServer != "" {
+		sources = append(sources, &source.IMAPSource{
+			Fs:          ing.Fs,
+			Server:      cfg.IMAPServer,
+			Username:    cfg.IMAPUser,
+			Password:    cfg.IMAPPassword,
+			Mailbox:     cfg.IMAPMailbox,
+			DownloadDir: filepath.Join(cfg.LearnDir, "imap"),
+			DryRun:      cfg.DryRun,
+		})
+	}
+
+	// Read the class list csv
+	csvfile, err := ing.Fs.Open(cfg.ClassList)
 	if err != nil {
-		log.Fatalln("Couldn't open the csv file", err)
+		return fmt.Errorf("couldn't open the csv file: %w", err)
 	}
+	defer csvfile.Close()
 	classlistcsv := csv.NewReader(csvfile)
-	
+
 	// Prepare data structures to hold the data
 	var examno = map[string]string{}
 	var submissions []parselearn.Submission
 	var bad_submissions []parselearn.Submission
+	var archiveLog []archiveRecord
+	var assemblyLog []assemblyRecord
+
+	// Load any manifest left by a previous run, so hash comparison for
+	// duplicate detection spans invocations rather than resetting each time
+	manifestPath := filepath.Join(cfg.OutputDir, "manifest.csv")
+	manifestEntries, err := manifest.Load(ing.Fs, manifestPath)
+	if err != nil {
+		return err
+	}
+	tracker := manifest.NewTracker(manifestEntries)
+
+	// auditLogger appends a tamper-evident record of every file placed in
+	// cfg.OutputDir, so a later dispute over a submission's provenance can be
+	// checked against a hash-chained log rather than just the manifest.
+	auditPath := filepath.Join(cfg.OutputDir, "audit.log")
+	auditLogger, err := audit.NewLogger(ing.Fs, auditPath)
+	if err != nil {
+		return err
+	}
+	auditLogger.DryRun = cfg.DryRun
+
+	// recordManifestEntry hashes a freshly-written output file, adds it to
+	// the manifest (flagging it if it duplicates another student's), and
+	// appends the move to the audit log.
+	recordManifestEntry := func(sourcePath string, path string, submission parselearn.Submission, mtimePreserved bool) {
+		hash, size, err := manifest.Hash(ing.Fs, path)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		tracker.Add(manifest.Entry{
+			ExamNumber:     submission.ExamNumber,
+			UUN:            submission.UUN,
+			SHA256:         hash,
+			Bytes:          size,
+			SourceFilename: submission.Filename,
+			SubmittedAt:    submission.DateSubmitted,
+		})
+
+		if err := auditLogger.Append(audit.Entry{
+			Timestamp:      time.Now().Format("2006-01-02-15-04-05"),
+			SourcePath:     sourcePath,
+			DestPath:       path,
+			SHA256:         hash,
+			MTimePreserved: mtimePreserved,
+		}); err != nil {
+			fmt.Println(err)
+		}
+	}
 
 	// Process each student in the class list csv
 	for {
@@ -123,9 +280,9 @@ func main() {
 			break
 		}
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-		
+
 		// todo - this relies on the columns being in a certain order - redo using gocarina/gocsv
 		student_uun := record[0]
 		student_examno := record[1]
@@ -133,210 +290,263 @@ func main() {
 		extratime_int, _ :=  strconv.Atoi(extratime)
 		fmt.Printf("%s -> %s (extra time: %s)\n", student_uun, student_examno, extratime)
 		examno[student_uun] = student_examno
-		
-		// check the Learn folder
-		if learn_file, ok := learn_files[student_uun]; ok {
-			fmt.Println(" - Learn file: ",learn_file)
-
-			// read the Learn receipt file
-			submission, err := parselearn.ParseLearnReceipt(learnDir+"/"+learn_file)
-			submission.ExamNumber = student_examno
-			submission.ExtraTime = extratime_int
-			
-			// Decide if the submission is LATE or not
-			sub_time, _ := time.Parse("2006-01-02-15-04-05", submission.DateSubmitted)
-			if(sub_time.After(deadline_time)) {
-				if(extratime_int > 0) {
-					// For students with extra time noted in the class list, their submission deadline is shifted
-					if(sub_time.After(deadline_time.Add(time.Minute * time.Duration(extratime_int)))) {
-						submission.LateSubmission = "LATE"
-					}
-				} else {
-					// For students with no allowance of extra time, their submission is marked late
-					submission.LateSubmission = "LATE"
-				}
+
+		// Try each source in priority order until one has something for this student
+		var candidate *source.SubmissionCandidate
+		for _, src := range sources {
+			candidates, err := src.Fetch(student_uun)
+			if err != nil {
+				fmt.Printf("Error fetching %s from %s: %v\n", student_uun, src.Name(), err)
+				continue
 			}
-			
-			if err == nil {
-				if submission.NumberOfFiles == 1 && submission.FiletypeError == "" {
-				
-					// We have one PDF for the student, so move it into place in the outputDir
-					
-					fmt.Println(" -- Submission: ",submission.Filename)
-					new_path := outputDir+"/"+student_examno+".pdf"
-					if (submission.LateSubmission == "LATE") {
-						new_path = outputDir+"/LATE-"+student_examno+".pdf"
-					}
-					filemovestatus := moveFile(learnDir+"/"+submission.Filename, new_path)
-					submission.OutputFile = filemovestatus
-					fmt.Println(" --- ", filemovestatus)
-					
-					// If the file move was OK, we can remove the Learn receipt as it's no longer needed
-					if(strings.Contains(filemovestatus, "File")) {
-						removeFile(learnDir+"/"+learn_file)
-					}
-					
-					// Add this record to the table of successes
-					submissions = append(submissions, submission)
-					
+			if len(candidates) > 0 {
+				candidate = &candidates[0]
+				break
+			}
+		}
+
+		if candidate == nil {
+			// No source had anything for this student
+			continue
+		}
+
+		submission := parselearn.Submission{}
+		submission.UUN = student_uun
+		submission.ExamNumber = student_examno
+		submission.ExtraTime = extratime_int
+		submission.DateSubmitted = candidate.SubmittedAt.Format("2006-01-02-15-04-05")
+
+		// Decide if the submission is LATE or not, the same way regardless of source
+		if isLate(candidate.SubmittedAt, deadline_time, extratime_int) {
+			submission.LateSubmission = "LATE"
+		}
+
+		fmt.Println(" - Source: ", candidate.Origin)
+
+		// A single file that is actually a zip/tar/rar of scans should be
+		// extracted before we decide whether this is a usable submission
+		if candidate.NumberOfFiles == 1 && archive.IsArchive(candidate.Files[0]) {
+			archiveType := archive.Type(candidate.Files[0])
+			sourceFilename := filepath.Base(candidate.Files[0])
+			extractDir := filepath.Join(cfg.LearnDir, "extracted", strings.ToLower(student_uun))
+
+			// archive.Extract writes real files straight to disk (it takes
+			// raw paths, not ing.Fs) - -dryrun must not call it.
+			if ing.DryRun {
+				fmt.Printf(" --- [dryrun] would extract %s archive into %s\n", archiveType, extractDir)
+			} else {
+				extracted, err := archive.Extract(candidate.Files[0], extractDir)
+				if err != nil {
+					fmt.Printf(" --- Failed to extract %s archive: %v\n", archiveType, err)
 				} else {
-					// There was a problem with this submission, so it will need investigation and manual work
-					
-					fmt.Println(" --- Bad submission: ",submission.NumberOfFiles, " files ", submission.FiletypeError)
-					bad_submissions = append(bad_submissions, submission)					
+					fmt.Printf(" --- Extracted %s archive into %d page(s)\n", archiveType, len(extracted))
+					candidate.Files = extracted
+					candidate.NumberOfFiles = len(extracted)
+					candidate.FiletypeError = ""
 				}
+			}
+			archiveLog = append(archiveLog, archiveRecord{
+				ExamNumber:     student_examno,
+				UUN:            student_uun,
+				ArchiveType:    archiveType,
+				SourceFilename: sourceFilename,
+			})
+		}
+
+		new_path := cfg.OutputDir+"/"+student_examno+".pdf"
+		if (submission.LateSubmission == "LATE") {
+			new_path = cfg.OutputDir+"/LATE-"+student_examno+".pdf"
+		}
+
+		// A submission split across several allowed files gets assembled into
+		// one PDF, which is then the submission - there is nothing left to move
+		if candidate.NumberOfFiles > 1 && assemble.Assemblable(candidate.Files) {
+			ordered := assemble.SortFiles(candidate.Files)
+
+			submission.NumberOfFiles = 1
+			submission.Filename = filepath.Base(new_path)
+
+			// assemble.MergeSubmission writes a brand new file straight to
+			// disk (it takes raw paths, not ing.Fs) - -dryrun must not call
+			// it, and there is then no real file to Chtimes or hash.
+			mtimePreserved := false
+			if ing.DryRun {
+				fmt.Printf(" --- [dryrun] would assemble %d files into %s\n", candidate.NumberOfFiles, new_path)
 			} else {
-				fmt.Printf("Error with %s: %v\n", learn_file, err)
+				if err := assemble.MergeSubmission(ordered, new_path); err != nil {
+					fmt.Printf(" --- Failed to assemble %d files: %v\n", candidate.NumberOfFiles, err)
+					submission.NumberOfFiles = candidate.NumberOfFiles
+					submission.FiletypeError = candidate.FiletypeError
+					bad_submissions = append(bad_submissions, submission)
+					continue
+				}
+
+				// MergeSubmission writes a brand new file, so its mtime is
+				// the time of assembly - reset it to the original
+				// submission time.
+				mtimePreserved = ing.Fs.Chtimes(new_path, candidate.SubmittedAt, candidate.SubmittedAt) == nil
+				fmt.Printf(" --- Assembled %d files into %s\n", candidate.NumberOfFiles, new_path)
 			}
-			
-		} else {
-			// No Learn submission from this student -- check for other sources
-			
-			// TODO - process for reading in submissions to MS Forms
-			
-			
-			// Last resort: look for manually-created UUN.pdf in the learnDir
-			
-			raw_uun_path := learnDir+"/"+strings.ToLower(student_uun)+".pdf"
-			if _, err := os.Stat(raw_uun_path); err == nil {
-				// Such a file exists, so create a dummy Submission for it and then move the PDF into place
-				manual_sub := parselearn.Submission{}
-				manual_sub.UUN = student_uun
-				manual_sub.ExamNumber = student_examno
-				filemovestatus := moveFile(raw_uun_path, outputDir+"/"+student_examno+".pdf")
-				manual_sub.OutputFile = filemovestatus
-				submissions = append(submissions, manual_sub)
+
+			assemblyLog = append(assemblyLog, assemblyRecord{
+				ExamNumber: student_examno,
+				UUN:        student_uun,
+				Sources:    strings.Join(baseNames(ordered), ";"),
+			})
+			for _, f := range ordered {
+				ing.removeFile(f)
 			}
+			if candidate.ReceiptPath != "" {
+				ing.removeFile(candidate.ReceiptPath)
+			}
+
+			submission.OutputFile = "File created"
+			if !ing.DryRun {
+				recordManifestEntry(strings.Join(ordered, ";"), new_path, submission, mtimePreserved)
+			}
+			submissions = append(submissions, submission)
+			continue
+		}
+
+		submission.NumberOfFiles = candidate.NumberOfFiles
+		submission.FiletypeError = candidate.FiletypeError
+		if len(candidate.Files) > 0 {
+			submission.Filename = filepath.Base(candidate.Files[0])
+		}
+
+		if candidate.NumberOfFiles == 1 && candidate.FiletypeError == "" {
+
+			// We have one PDF for the student, so move it into place in the cfg.OutputDir
+
+			fmt.Println(" -- Submission: ", submission.Filename)
+			filemovestatus, mtimePreserved := ing.moveFile(candidate.Files[0], new_path, candidate.SubmittedAt)
+			submission.OutputFile = filemovestatus
+			fmt.Println(" --- ", filemovestatus)
+
+			// If the file move was OK, we can remove the Learn receipt as it's no longer needed
+			if(strings.Contains(filemovestatus, "File") && candidate.ReceiptPath != "") {
+				ing.removeFile(candidate.ReceiptPath)
+			}
+
+			// Add this record to the table of successes. There is no real
+			// file at new_path to hash under -dryrun, so skip the manifest
+			// and audit log rather than logging a spurious "not found".
+			if strings.Contains(filemovestatus, "File") && !ing.DryRun {
+				recordManifestEntry(candidate.Files[0], new_path, submission, mtimePreserved)
+			}
+			submissions = append(submissions, submission)
+
+		} else {
+			// There was a problem with this submission, so it will need investigation and manual work
+
+			fmt.Println(" --- Bad submission: ", submission.NumberOfFiles, " files ", submission.FiletypeError)
+			bad_submissions = append(bad_submissions, submission)
 		}
-		
+
 	}
-	
+
 	fmt.Println("\n\nSuccessful submissions: ", len(submissions))
 	fmt.Println("\n\nBad submissions: ", len(bad_submissions))
-	
+
 	// TODO - remove timestamp from filename, and have it as a column in the csv. Make this just append details to csv file if it exists
 	report_time := time.Now().Format("2006-01-02-15-04-05")
-	parselearn.WriteSubmissionsToCSV(submissions, fmt.Sprintf("%s/%s-learn-success.csv", outputDir, report_time))
-	parselearn.WriteSubmissionsToCSV(bad_submissions, fmt.Sprintf("%s/%s-learn-errors.csv", outputDir, report_time))
-
-	
-	// That's enough
-	os.Exit(0)
-	
+	parselearn.WriteSubmissionsToCSV(submissions, fmt.Sprintf("%s/%s-learn-success.csv", cfg.OutputDir, report_time))
+	parselearn.WriteSubmissionsToCSV(bad_submissions, fmt.Sprintf("%s/%s-learn-errors.csv", cfg.OutputDir, report_time))
+	writeArchiveLog(archiveLog, fmt.Sprintf("%s/%s-archives.csv", cfg.OutputDir, report_time))
+	writeAssemblyLog(assemblyLog, fmt.Sprintf("%s/%s-assembly.csv", cfg.OutputDir, report_time))
 
+	if err := manifest.Write(ing.Fs, manifestPath, tracker.Entries); err != nil {
+		fmt.Println(err)
+	}
+	writeDuplicateLog(tracker.Duplicates, fmt.Sprintf("%s/%s-duplicates.csv", cfg.OutputDir, report_time))
 
+	return nil
 }
 
-// Move the path_from file to path_to, but only if there is not already a file at path_to
-func moveFile(path_from string, path_to string) string {
-
-	// Check path_from exists, and its age
-	file_from, err := os.Stat(path_from)
-	check(err)
-    time_from := file_from.ModTime()
-	
-	// If there is a file at path_to, check its age. If it is newer than the path_from file, then don't bother copying
-	file_to_exists := false
-    if file_to, err := os.Stat(path_to); err == nil {
-		file_to_exists = true
-		time_to := file_to.ModTime()
-		if(time_to.After(time_from)) {
-			// No need to copy over, but delete the path_from file since it is not needed
-			removeFile(path_from)
-			return "File already exists"
-		}
-    }
-	
-	// Now copy the path_from file into the path_to location
-	err = CopyFile(path_from, path_to)
+// writeArchiveLog records the outcome of each archive extraction attempted
+// during the run, if any were.
+func writeArchiveLog(records []archiveRecord, path string) {
+	if len(records) == 0 {
+		return
+	}
+
+	f, err := os.Create(path)
 	if err != nil {
-		fmt.Printf("CopyFile failed %q\n", err)
-	} else {
-		// Get rid of the path_from file, it's no longer needed
-		removeFile(path_from)
-		if(file_to_exists) {
-			return "File replaced"
-		} else {
-			return "File created"
-		}
+		fmt.Println(err)
+		return
 	}
-	
-	return "Done Nothing"
-}
+	defer f.Close()
 
-func removeFile(path string) {
-	err := os.Remove(path)
-	check(err)
-	return
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	w.Write([]string{"ExamNumber", "UUN", "ArchiveType", "SourceFilename"})
+	for _, r := range records {
+		w.Write([]string{r.ExamNumber, r.UUN, r.ArchiveType, r.SourceFilename})
+	}
 }
 
-	
-func check(e error) {
-    if e != nil {
-        panic(e)
-    }
+// writeAssemblyLog records which source files were merged into each
+// multi-file submission, if any were.
+func writeAssemblyLog(records []assemblyRecord, path string) {
+	if len(records) == 0 {
+		return
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	w.Write([]string{"ExamNumber", "UUN", "Sources"})
+	for _, r := range records {
+		w.Write([]string{r.ExamNumber, r.UUN, r.Sources})
+	}
 }
 
+// writeDuplicateLog records every submission whose output file's SHA-256
+// matches another student's, if any were found.
+func writeDuplicateLog(records []manifest.Duplicate, path string) {
+	if len(records) == 0 {
+		return
+	}
 
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer f.Close()
 
-// File copy functions - https://stackoverflow.com/a/21067803
-
-// CopyFile copies a file from src to dst. If src and dst files exist, and are
-// the same, then return success. Otherise, attempt to create a hard link
-// between the two files. If that fail, copy the file contents from src to dst.
-func CopyFile(src, dst string) (err error) {
-    sfi, err := os.Stat(src)
-    if err != nil {
-        return
-    }
-    if !sfi.Mode().IsRegular() {
-        // cannot copy non-regular files (e.g., directories,
-        // symlinks, devices, etc.)
-        return fmt.Errorf("CopyFile: non-regular source file %s (%q)", sfi.Name(), sfi.Mode().String())
-    }
-    dfi, err := os.Stat(dst)
-    if err != nil {
-        if !os.IsNotExist(err) {
-            return
-        }
-    } else {
-        if !(dfi.Mode().IsRegular()) {
-            return fmt.Errorf("CopyFile: non-regular destination file %s (%q)", dfi.Name(), dfi.Mode().String())
-        }
-        if os.SameFile(sfi, dfi) {
-            return
-        }
-    }
-    if err = os.Link(src, dst); err == nil {
-        return
-    }
-    err = copyFileContents(src, dst)
-    return
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	w.Write([]string{"ExamNumber", "UUN", "SHA256", "DuplicateOfExamNumber", "DuplicateOfUUN"})
+	for _, r := range records {
+		w.Write([]string{r.ExamNumber, r.UUN, r.SHA256, r.DuplicateOfExamNumber, r.DuplicateOfUUN})
+	}
+}
+
+// isLate applies the same late/extra-time rule regardless of which Source a
+// submission came from: late if submitted after the deadline, shifted by the
+// student's allowance of extra time minutes (if any).
+func isLate(submittedAt time.Time, deadline time.Time, extraMinutes int) bool {
+	if !submittedAt.After(deadline) {
+		return false
+	}
+	if extraMinutes > 0 {
+		return submittedAt.After(deadline.Add(time.Minute * time.Duration(extraMinutes)))
+	}
+	return true
 }
 
-// copyFileContents copies the contents of the file named src to the file named
-// by dst. The file will be created if it does not already exist. If the
-// destination file exists, all it's contents will be replaced by the contents
-// of the source file.
-func copyFileContents(src, dst string) (err error) {
-    in, err := os.Open(src)
-    if err != nil {
-        return
-    }
-    defer in.Close()
-    out, err := os.Create(dst)
-    if err != nil {
-        return
-    }
-    defer func() {
-        cerr := out.Close()
-        if err == nil {
-            err = cerr
-        }
-    }()
-    if _, err = io.Copy(out, in); err != nil {
-        return
-    }
-    err = out.Sync()
-    return
+func check(e error) {
+	if e != nil {
+		panic(e)
+	}
 }
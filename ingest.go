@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Ingester performs every filesystem mutation gradex-ingest makes while
+// moving submissions into place. Fs is a real filesystem in normal
+// operation; tests substitute afero.NewMemMapFs(), and -dryrun wraps the
+// real filesystem in afero.NewReadOnlyFs() so a move/remove is logged
+// instead of touching disk.
+type Ingester struct {
+	Fs     afero.Fs
+	DryRun bool
+}
+
+// NewIngester returns an Ingester backed by the real filesystem, or (if
+// dryRun) one that only logs the moves and removals it would have made.
+func NewIngester(dryRun bool) *Ingester {
+	if dryRun {
+		return &Ingester{Fs: afero.NewReadOnlyFs(afero.NewOsFs()), DryRun: true}
+	}
+	return &Ingester{Fs: afero.NewOsFs()}
+}
+
+// pr-pal @ https://stackoverflow.com/questions/37932551/mkdir-if-not-exists-using-golang
+func (ing *Ingester) ensureDir(dirName string) error {
+	if ing.DryRun {
+		fmt.Println("[dryrun] would create dir", dirName)
+		return nil
+	}
+
+	err := ing.Fs.Mkdir(dirName, 0700) //probably umasked with 22 not 02
+
+	ing.Fs.Chmod(dirName, 0700)
+
+	if err == nil || os.IsExist(err) {
+		return nil
+	} else {
+		return err
+	}
+}
+
+// moveFile moves path_from to path_to, but only if there is not already a
+// file at path_to. The destination's mtime is set to preferredTime (the
+// original submission time, where known) rather than the time of the copy,
+// so a stat of the anonymised file still shows when the student submitted
+// it; pass a zero time.Time to fall back to path_from's own mtime. It
+// reports whether the mtime was actually preserved, for the audit log.
+func (ing *Ingester) moveFile(path_from string, path_to string, preferredTime time.Time) (string, bool) {
+
+	// Check path_from exists, and its age
+	file_from, err := ing.Fs.Stat(path_from)
+	check(err)
+	time_from := file_from.ModTime()
+
+	mtime := time_from
+	if !preferredTime.IsZero() {
+		mtime = preferredTime
+	}
+
+	// If there is a file at path_to, check its age. If it is newer than the path_from file, then don't bother copying
+	file_to_exists := false
+	if file_to, err := ing.Fs.Stat(path_to); err == nil {
+		file_to_exists = true
+		time_to := file_to.ModTime()
+		if time_to.After(time_from) {
+			// No need to copy over, but delete the path_from file since it is not needed
+			ing.removeFile(path_from)
+			return "File already exists", false
+		}
+	}
+
+	if ing.DryRun {
+		fmt.Printf("[dryrun] would move %s -> %s\n", path_from, path_to)
+		if file_to_exists {
+			return "File replaced", false
+		}
+		return "File created", false
+	}
+
+	// Now copy the path_from file into the path_to location
+	err = ing.copyFile(path_from, path_to)
+	if err != nil {
+		fmt.Printf("CopyFile failed %q\n", err)
+	} else {
+		mtimePreserved := ing.Fs.Chtimes(path_to, mtime, mtime) == nil
+
+		// Get rid of the path_from file, it's no longer needed
+		ing.removeFile(path_from)
+		if file_to_exists {
+			return "File replaced", mtimePreserved
+		} else {
+			return "File created", mtimePreserved
+		}
+	}
+
+	return "Done Nothing", false
+}
+
+func (ing *Ingester) removeFile(path string) {
+	if ing.DryRun {
+		fmt.Println("[dryrun] would remove", path)
+		return
+	}
+	err := ing.Fs.Remove(path)
+	check(err)
+}
+
+// copyFile copies a file from src to dst. If src and dst files exist, and are
+// the same, then return success. afero has no portable equivalent of
+// os.Link, so - unlike the os.* version this replaces - it always copies
+// the file's contents rather than attempting a hard link first.
+func (ing *Ingester) copyFile(src, dst string) (err error) {
+	sfi, err := ing.Fs.Stat(src)
+	if err != nil {
+		return
+	}
+	if !sfi.Mode().IsRegular() {
+		// cannot copy non-regular files (e.g., directories,
+		// symlinks, devices, etc.)
+		return fmt.Errorf("copyFile: non-regular source file %s (%q)", sfi.Name(), sfi.Mode().String())
+	}
+
+	dfi, err := ing.Fs.Stat(dst)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return
+		}
+	} else if !dfi.Mode().IsRegular() {
+		return fmt.Errorf("copyFile: non-regular destination file %s (%q)", dfi.Name(), dfi.Mode().String())
+	}
+
+	return ing.copyFileContents(src, dst)
+}
+
+// copyFileContents copies the contents of the file named src to the file named
+// by dst. The file will be created if it does not already exist. If the
+// destination file exists, all it's contents will be replaced by the contents
+// of the source file.
+func (ing *Ingester) copyFileContents(src, dst string) (err error) {
+	in, err := ing.Fs.Open(src)
+	if err != nil {
+		return
+	}
+	defer in.Close()
+	out, err := ing.Fs.Create(dst)
+	if err != nil {
+		return
+	}
+	defer func() {
+		cerr := out.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+	if _, err = io.Copy(out, in); err != nil {
+		return
+	}
+	return
+}
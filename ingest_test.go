@@ -0,0 +1,163 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestIngesterEnsureDir(t *testing.T) {
+	ing := &Ingester{Fs: afero.NewMemMapFs()}
+
+	if err := ing.ensureDir("output_dir"); err != nil {
+		t.Fatalf("ensureDir: %v", err)
+	}
+	info, err := ing.Fs.Stat("output_dir")
+	if err != nil {
+		t.Fatalf("stat after ensureDir: %v", err)
+	}
+	if !info.IsDir() {
+		t.Fatalf("expected output_dir to be a directory")
+	}
+
+	// Calling it again on an existing directory should not error.
+	if err := ing.ensureDir("output_dir"); err != nil {
+		t.Fatalf("ensureDir on existing dir: %v", err)
+	}
+}
+
+func TestIngesterEnsureDirDryRun(t *testing.T) {
+	ing := &Ingester{Fs: afero.NewReadOnlyFs(afero.NewMemMapFs()), DryRun: true}
+
+	if err := ing.ensureDir("output_dir"); err != nil {
+		t.Fatalf("ensureDir dry-run: %v", err)
+	}
+	if _, err := ing.Fs.Stat("output_dir"); err == nil {
+		t.Fatalf("dry-run ensureDir should not have created output_dir")
+	}
+}
+
+func TestIngesterMoveFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ing := &Ingester{Fs: fs}
+
+	afero.WriteFile(fs, "from.pdf", []byte("hello"), 0600)
+
+	status, mtimePreserved := ing.moveFile("from.pdf", "to.pdf", time.Time{})
+	if status != "File created" {
+		t.Fatalf("moveFile status = %q, want %q", status, "File created")
+	}
+	if !mtimePreserved {
+		t.Errorf("mtimePreserved = false, want true")
+	}
+
+	if _, err := fs.Stat("from.pdf"); err == nil {
+		t.Fatalf("from.pdf should have been removed after move")
+	}
+	got, err := afero.ReadFile(fs, "to.pdf")
+	if err != nil {
+		t.Fatalf("reading to.pdf: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Fatalf("to.pdf contents = %q, want %q", got, "hello")
+	}
+}
+
+func TestIngesterMoveFilePreservesPreferredTime(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ing := &Ingester{Fs: fs}
+
+	afero.WriteFile(fs, "from.pdf", []byte("hello"), 0600)
+	submittedAt := time.Unix(12345, 0)
+
+	status, mtimePreserved := ing.moveFile("from.pdf", "to.pdf", submittedAt)
+	if status != "File created" || !mtimePreserved {
+		t.Fatalf("moveFile = (%q, %v), want (%q, true)", status, mtimePreserved, "File created")
+	}
+
+	info, err := fs.Stat("to.pdf")
+	if err != nil {
+		t.Fatalf("stat to.pdf: %v", err)
+	}
+	if !info.ModTime().Equal(submittedAt) {
+		t.Errorf("to.pdf mtime = %v, want %v", info.ModTime(), submittedAt)
+	}
+}
+
+func TestIngesterMoveFileDoesNotOverwriteNewer(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ing := &Ingester{Fs: fs}
+
+	afero.WriteFile(fs, "from.pdf", []byte("old"), 0600)
+	fs.Chtimes("from.pdf", time.Time{}, time.Unix(1000, 0))
+
+	afero.WriteFile(fs, "to.pdf", []byte("new"), 0600)
+	fs.Chtimes("to.pdf", time.Time{}, time.Unix(2000, 0))
+
+	status, _ := ing.moveFile("from.pdf", "to.pdf", time.Time{})
+	if status != "File already exists" {
+		t.Fatalf("moveFile status = %q, want %q", status, "File already exists")
+	}
+
+	got, err := afero.ReadFile(fs, "to.pdf")
+	if err != nil {
+		t.Fatalf("reading to.pdf: %v", err)
+	}
+	if string(got) != "new" {
+		t.Fatalf("to.pdf should have been left untouched, got %q", got)
+	}
+	if _, err := fs.Stat("from.pdf"); err == nil {
+		t.Fatalf("from.pdf should have been removed even though it wasn't copied")
+	}
+}
+
+func TestIngesterMoveFileDryRun(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "from.pdf", []byte("hello"), 0600)
+	ing := &Ingester{Fs: afero.NewReadOnlyFs(fs), DryRun: true}
+
+	status, mtimePreserved := ing.moveFile("from.pdf", "to.pdf", time.Time{})
+	if status != "File created" {
+		t.Fatalf("moveFile dry-run status = %q, want %q", status, "File created")
+	}
+	if mtimePreserved {
+		t.Errorf("mtimePreserved = true, want false (dry-run should not touch anything)")
+	}
+	if _, err := fs.Stat("to.pdf"); err == nil {
+		t.Fatalf("dry-run moveFile should not have created to.pdf")
+	}
+	if _, err := fs.Stat("from.pdf"); err != nil {
+		t.Fatalf("dry-run moveFile should not have removed from.pdf: %v", err)
+	}
+}
+
+func TestIsLate(t *testing.T) {
+	deadline, _ := time.Parse("2006-01-02-15-04", "2020-04-22-16-00")
+
+	cases := []struct {
+		name         string
+		submittedAt  string
+		extraMinutes int
+		want         bool
+	}{
+		{"before deadline", "2020-04-22-15-00", 0, false},
+		{"at deadline", "2020-04-22-16-00", 0, false},
+		{"after deadline, no extra time", "2020-04-22-16-01", 0, true},
+		{"after deadline, within extra time", "2020-04-22-16-20", 30, false},
+		{"after deadline, beyond extra time", "2020-04-22-16-40", 30, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			submittedAt, err := time.Parse("2006-01-02-15-04", c.submittedAt)
+			if err != nil {
+				t.Fatalf("parsing submittedAt: %v", err)
+			}
+			got := isLate(submittedAt, deadline, c.extraMinutes)
+			if got != c.want {
+				t.Errorf("isLate(%s) = %v, want %v", c.submittedAt, got, c.want)
+			}
+		})
+	}
+}
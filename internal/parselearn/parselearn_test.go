@@ -0,0 +1,60 @@
+package parselearn
+
+import "testing"
+
+func TestProcessName(t *testing.T) {
+	sub := Submission{}
+	processName("Name: First Last (s1234567)", &sub)
+
+	if sub.FirstName != "First" || sub.LastName != "Last" || sub.Matriculation != "s1234567" {
+		t.Errorf("got %+v", sub)
+	}
+}
+
+func TestProcessAssignment(t *testing.T) {
+	sub := Submission{}
+	processAssignment("Assignment: Practice Exam Drop Box", &sub)
+
+	if sub.Assignment != "Practice Exam Drop Box" {
+		t.Errorf("Assignment = %q", sub.Assignment)
+	}
+}
+
+func TestProcessDateSubmitted(t *testing.T) {
+	sub := Submission{}
+	processDateSubmitted("Date Submitted: Monday, 22 April 2020 15:30:00 o'clock BST", &sub)
+
+	want := "Monday, 22 April 2020 15:30:00 o'clock BST"
+	if sub.DateSubmitted != want {
+		t.Errorf("DateSubmitted = %q, want %q", sub.DateSubmitted, want)
+	}
+}
+
+func TestParseLearnReceipt(t *testing.T) {
+	sub, err := ParseLearnReceipt("testdata/receipt1.txt")
+	if err != nil {
+		t.Fatalf("ParseLearnReceipt: %v", err)
+	}
+
+	if sub.FirstName != "First" || sub.LastName != "Last" || sub.Matriculation != "s1234567" {
+		t.Errorf("got names %+v", sub)
+	}
+	if sub.Assignment != "Practice Exam Drop Box" {
+		t.Errorf("Assignment = %q", sub.Assignment)
+	}
+	if sub.OriginalFilename != "OnlineExam-B123456.pdf" {
+		t.Errorf("OriginalFilename = %q", sub.OriginalFilename)
+	}
+	if sub.Filename != "Practice Exam Drop Box_s1234567_attempt_2020-04-22-15-30-00_OnlineExam-B123456.pdf" {
+		t.Errorf("Filename = %q", sub.Filename)
+	}
+	if sub.NumberOfFiles != 1 {
+		t.Errorf("NumberOfFiles = %d, want 1", sub.NumberOfFiles)
+	}
+}
+
+func TestParseLearnReceiptMissingFile(t *testing.T) {
+	if _, err := ParseLearnReceipt("testdata/does-not-exist.txt"); err == nil {
+		t.Fatal("expected an error for a missing receipt")
+	}
+}
@@ -0,0 +1,80 @@
+// Package archive extracts zipped or tarred exam submissions so their pages
+// can be handed to the PDF-assembly pipeline instead of being rejected as a
+// bad submission.
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mholt/archiver/v3"
+)
+
+// extensions maps a lower-cased file extension to the archiver.Writer/Reader
+// name mholt/archiver registers for it, for the formats this package supports.
+var extensions = map[string]string{
+	".zip":     "zip",
+	".tar":     "tar",
+	".tar.gz":  "tar.gz",
+	".tgz":     "tar.gz",
+	".tar.bz2": "tar.bz2",
+	".rar":     "rar",
+}
+
+// pageExtensions are the file types that can form a page of a submission.
+var pageExtensions = map[string]bool{
+	".pdf":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+}
+
+// Type returns the archive type (e.g. "zip", "tar.gz") for path, or "" if it
+// is not a supported archive.
+func Type(path string) string {
+	lower := strings.ToLower(path)
+	for ext, kind := range extensions {
+		if strings.HasSuffix(lower, ext) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// IsArchive reports whether path has a supported archive extension.
+func IsArchive(path string) bool {
+	return Type(path) != ""
+}
+
+// Extract unpacks archivePath into destDir (which is created if needed) and
+// returns the paths of the PDF and image files found inside, naturally
+// sorted by filename. Any other file in the archive is ignored.
+func Extract(archivePath string, destDir string) ([]string, error) {
+	if err := os.MkdirAll(destDir, 0700); err != nil {
+		return nil, err
+	}
+
+	if err := archiver.Unarchive(archivePath, destDir); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err := filepath.Walk(destDir, func(path string, f os.FileInfo, err error) error {
+		if err != nil || f.IsDir() {
+			return err
+		}
+		if pageExtensions[strings.ToLower(filepath.Ext(path))] {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
@@ -0,0 +1,101 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholt/archiver/v3"
+)
+
+func TestType(t *testing.T) {
+	cases := map[string]string{
+		"submission.zip":     "zip",
+		"submission.ZIP":     "zip",
+		"submission.tar":     "tar",
+		"submission.tar.gz":  "tar.gz",
+		"submission.tgz":     "tar.gz",
+		"submission.tar.bz2": "tar.bz2",
+		"submission.rar":     "rar",
+		"submission.pdf":     "",
+	}
+	for path, want := range cases {
+		if got := Type(path); got != want {
+			t.Errorf("Type(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestIsArchive(t *testing.T) {
+	if !IsArchive("submission.zip") {
+		t.Error("submission.zip should be an archive")
+	}
+	if IsArchive("submission.pdf") {
+		t.Error("submission.pdf should not be an archive")
+	}
+}
+
+// zipFixture builds a zip at dir/name.zip containing the given files (name ->
+// content) and returns its path.
+func zipFixture(t *testing.T, dir string, name string, files map[string]string) string {
+	t.Helper()
+
+	srcDir := filepath.Join(dir, "src-"+name)
+	if err := os.MkdirAll(srcDir, 0700); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	var sources []string
+	for filename, content := range files {
+		path := filepath.Join(srcDir, filename)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		sources = append(sources, path)
+	}
+
+	zipPath := filepath.Join(dir, name+".zip")
+	if err := archiver.Archive(sources, zipPath); err != nil {
+		t.Fatalf("archiver.Archive: %v", err)
+	}
+	return zipPath
+}
+
+func TestExtractReturnsPageFilesOnly(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := zipFixture(t, dir, "pages", map[string]string{
+		"page2.jpg":   "jpg",
+		"page10.jpg":  "jpg",
+		"page1.jpg":   "jpg",
+		"readme.docx": "not a page",
+	})
+
+	files, err := Extract(zipPath, filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("got %d files, want 3: %v", len(files), files)
+	}
+	for _, f := range files {
+		if filepath.Ext(f) != ".jpg" {
+			t.Errorf("non-page file returned: %s", f)
+		}
+	}
+}
+
+func TestExtractNoPageFiles(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := zipFixture(t, dir, "nopages", map[string]string{
+		"notes.docx": "not a page",
+		"notes.txt":  "not a page either",
+	})
+
+	files, err := Extract(zipPath, filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("got %d files, want 0: %v", len(files), files)
+	}
+}
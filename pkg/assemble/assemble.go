@@ -0,0 +1,228 @@
+// Package assemble merges a multi-file submission (scanned pages split
+// across several PDFs and/or photos) into the single PDF that gradex-ingest
+// expects to anonymise and hand to markers.
+package assemble
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/unidoc/unipdf/v3/creator"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// dpi is the resolution assumed when sizing a scanned image to a PDF page.
+const dpi = 200.0
+const pointsPerInch = 72.0
+
+// naturalChunk splits a filename into alternating runs of digits and
+// non-digits, so "page10.jpg" sorts after "page9.jpg".
+var naturalChunk = regexp.MustCompile(`\d+|\D+`)
+
+// SortFiles orders files for assembly. Learn gives no reliable ordering
+// metadata beyond the filenames it assigns, so this falls back to a natural
+// sort: runs of digits compare numerically rather than lexically.
+func SortFiles(files []string) []string {
+	sorted := make([]string, len(files))
+	copy(sorted, files)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return naturalLess(filepath.Base(sorted[i]), filepath.Base(sorted[j]))
+	})
+
+	return sorted
+}
+
+func naturalLess(a, b string) bool {
+	as := naturalChunk.FindAllString(a, -1)
+	bs := naturalChunk.FindAllString(b, -1)
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		if aErr == nil && bErr == nil {
+			return an < bn
+		}
+		return as[i] < bs[i]
+	}
+	return len(as) < len(bs)
+}
+
+// allowedExtensions are the file types MergeSubmission knows how to place on
+// a page.
+var allowedExtensions = map[string]bool{
+	".pdf":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+}
+
+// Assemblable reports whether every file in files is a type MergeSubmission
+// can handle.
+func Assemblable(files []string) bool {
+	for _, f := range files {
+		if !allowedExtensions[strings.ToLower(filepath.Ext(f))] {
+			return false
+		}
+	}
+	return len(files) > 0
+}
+
+// MergeSubmission assembles files - PDFs and/or JPEG/PNG images - into a
+// single PDF at outputPath, in the order returned by SortFiles. Any file
+// that is not a PDF, JPEG or PNG is rejected.
+func MergeSubmission(files []string, outputPath string) error {
+	c := creator.New()
+
+	for _, path := range SortFiles(files) {
+		ext := strings.ToLower(filepath.Ext(path))
+		switch ext {
+		case ".pdf":
+			if err := appendPDF(c, path); err != nil {
+				return fmt.Errorf("assemble: %s: %w", path, err)
+			}
+		case ".jpg", ".jpeg", ".png":
+			if err := appendImage(c, path); err != nil {
+				return fmt.Errorf("assemble: %s: %w", path, err)
+			}
+		default:
+			return fmt.Errorf("assemble: %s: unsupported file type %q", path, ext)
+		}
+	}
+
+	return c.WriteToFile(outputPath)
+}
+
+// appendPDF copies every page of path onto the end of c. Like countPages in
+// the root package, it decrypts with a blank owner password first: scanners
+// commonly export PDFs that are trivially encrypted this way.
+func appendPDF(c *creator.Creator, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		return err
+	}
+
+	isEncrypted, err := reader.IsEncrypted()
+	if err != nil {
+		return err
+	}
+	if isEncrypted {
+		if _, err := reader.Decrypt([]byte("")); err != nil {
+			return err
+		}
+	}
+
+	numPages, err := reader.GetNumPages()
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i <= numPages; i++ {
+		page, err := reader.GetPage(i)
+		if err != nil {
+			return err
+		}
+		if err := c.AddPage(page); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appendImage adds path as a single full-page image, sized as if scanned at
+// dpi, after correcting for any EXIF orientation tag.
+func appendImage(c *creator.Creator, path string) error {
+	oriented, cleanup, err := correctOrientation(path)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	img, err := c.NewImageFromFile(oriented)
+	if err != nil {
+		return err
+	}
+
+	widthPt := img.Width() / dpi * pointsPerInch
+	heightPt := img.Height() / dpi * pointsPerInch
+
+	c.SetPageSize(creator.PageSize{widthPt, heightPt})
+	c.NewPage()
+
+	img.SetWidth(widthPt)
+	img.SetHeight(heightPt)
+	img.SetPos(0, 0)
+
+	return c.Draw(img)
+}
+
+// correctOrientation returns the path to a version of path with its pixels
+// rotated upright per its EXIF orientation tag, and a cleanup func to remove
+// any temp file that was created. Images with no EXIF orientation tag (most
+// scans, and all PNGs) are returned unchanged.
+func correctOrientation(path string) (string, func(), error) {
+	noop := func() {}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", noop, err
+	}
+	x, err := exif.Decode(f)
+	f.Close()
+	if err != nil {
+		return path, noop, nil
+	}
+
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return path, noop, nil
+	}
+	orientation, err := tag.Int(0)
+	if err != nil || orientation == 1 {
+		return path, noop, nil
+	}
+
+	img, err := imaging.Open(path)
+	if err != nil {
+		return "", noop, err
+	}
+
+	switch orientation {
+	case 3:
+		img = imaging.Rotate180(img)
+	case 6:
+		img = imaging.Rotate270(img)
+	case 8:
+		img = imaging.Rotate90(img)
+	}
+
+	tmp, err := os.CreateTemp("", "gradex-ingest-oriented-*"+filepath.Ext(path))
+	if err != nil {
+		return "", noop, err
+	}
+	defer tmp.Close()
+
+	if err := imaging.Encode(tmp, img, imaging.JPEG); err != nil {
+		os.Remove(tmp.Name())
+		return "", noop, err
+	}
+
+	return tmp.Name(), func() { os.Remove(tmp.Name()) }, nil
+}
@@ -0,0 +1,48 @@
+package assemble
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSortFilesNatural(t *testing.T) {
+	in := []string{"page10.jpg", "page2.jpg", "page1.jpg", "page9.jpg"}
+	want := []string{"page1.jpg", "page2.jpg", "page9.jpg", "page10.jpg"}
+
+	got := SortFiles(in)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("SortFiles(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestAssemblable(t *testing.T) {
+	if !Assemblable([]string{"a.pdf", "b.jpg", "c.PNG"}) {
+		t.Error("pdf/jpg/png should be assemblable")
+	}
+	if Assemblable([]string{"a.pdf", "b.docx"}) {
+		t.Error("a docx in the mix should not be assemblable")
+	}
+	if Assemblable(nil) {
+		t.Error("no files should not be assemblable")
+	}
+}
+
+// MergeSubmission's happy path drives unipdf's creator all the way to
+// WriteToFile, which refuses to run without a licensed unipdf build - not
+// available in this environment - so it isn't covered here beyond the error
+// path below, which returns before any creator calls that need one.
+
+func TestMergeSubmissionRejectsUnsupportedType(t *testing.T) {
+	dir := t.TempDir()
+	docx := filepath.Join(dir, "notes.docx")
+	if err := os.WriteFile(docx, []byte("not a page"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := MergeSubmission([]string{docx}, filepath.Join(dir, "merged.pdf")); err == nil {
+		t.Fatal("expected an error for an unsupported file type")
+	}
+}
@@ -0,0 +1,115 @@
+// Package audit maintains a single, append-only, hash-chained log of every
+// file gradex-ingest places in outputDir, so that an exam-board dispute over
+// a submission's provenance can be checked against tamper-evident evidence:
+// editing or removing any line breaks the chain from that point on.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/afero"
+)
+
+// Entry is one line of audit.log: one file moved or assembled into outputDir.
+type Entry struct {
+	Timestamp      string
+	SourcePath     string
+	DestPath       string
+	SHA256         string
+	MTimePreserved bool
+}
+
+var header = []string{"Timestamp", "SourcePath", "DestPath", "SHA256", "MTimePreserved", "PrevChainHash", "ChainHash"}
+
+// Logger appends Entries to a hash-chained audit.log. Each line's ChainHash
+// covers the entry and the previous line's ChainHash, so recomputing the
+// chain from the top detects any edit to an earlier line.
+type Logger struct {
+	Fs       afero.Fs
+	Path     string
+	DryRun   bool
+	prevHash string
+}
+
+// NewLogger opens the audit log at path, picking up the chain from its last
+// line if the log already exists, so the chain spans invocations of
+// gradex-ingest rather than resetting on every run.
+func NewLogger(fs afero.Fs, path string) (*Logger, error) {
+	l := &Logger{Fs: fs, Path: path}
+
+	f, err := fs.Open(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > 1 {
+		l.prevHash = rows[len(rows)-1][6]
+	}
+
+	return l, nil
+}
+
+// Append chains entry onto the log and writes it immediately, so the log
+// reflects every action taken even if the run is interrupted partway through.
+func (l *Logger) Append(entry Entry) error {
+	if l.DryRun {
+		fmt.Println("[dryrun] would append to audit log:", entry.SourcePath, "->", entry.DestPath)
+		return nil
+	}
+
+	exists, err := afero.Exists(l.Fs, l.Path)
+	if err != nil {
+		return err
+	}
+
+	f, err := l.Fs.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	if !exists {
+		if err := w.Write(header); err != nil {
+			return err
+		}
+	}
+
+	chainHash := chain(l.prevHash, entry)
+	if err := w.Write([]string{
+		entry.Timestamp,
+		entry.SourcePath,
+		entry.DestPath,
+		entry.SHA256,
+		strconv.FormatBool(entry.MTimePreserved),
+		l.prevHash,
+		chainHash,
+	}); err != nil {
+		return err
+	}
+
+	l.prevHash = chainHash
+	return nil
+}
+
+// chain hashes entry together with the previous line's chain hash, so that
+// changing any earlier line changes every chain hash that follows it.
+func chain(prevHash string, entry Entry) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s|%s|%s|%t|%s", entry.Timestamp, entry.SourcePath, entry.DestPath, entry.SHA256, entry.MTimePreserved, prevHash)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestLoggerAppendChainsEntries(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	l, err := NewLogger(fs, "audit.log")
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+
+	first := Entry{Timestamp: "2020-04-22-15-00-00", SourcePath: "a.pdf", DestPath: "B123456.pdf", SHA256: "hash1", MTimePreserved: true}
+	if err := l.Append(first); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	second := Entry{Timestamp: "2020-04-22-15-05-00", SourcePath: "b.pdf", DestPath: "B765432.pdf", SHA256: "hash2", MTimePreserved: false}
+	if err := l.Append(second); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	rows, err := afero.ReadFile(fs, "audit.log")
+	if err != nil {
+		t.Fatalf("reading audit.log: %v", err)
+	}
+	if len(rows) == 0 {
+		t.Fatalf("audit.log is empty")
+	}
+
+	// Reopening the log should pick up the chain where it left off, rather
+	// than starting a fresh chain with an empty PrevChainHash.
+	l2, err := NewLogger(fs, "audit.log")
+	if err != nil {
+		t.Fatalf("NewLogger (reopen): %v", err)
+	}
+	if l2.prevHash == "" {
+		t.Fatalf("reopened logger should have picked up the previous chain hash")
+	}
+	if l2.prevHash != l.prevHash {
+		t.Errorf("reopened prevHash = %q, want %q", l2.prevHash, l.prevHash)
+	}
+}
+
+func TestLoggerAppendDryRunDoesNotWrite(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	l, err := NewLogger(fs, "audit.log")
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	l.DryRun = true
+
+	if err := l.Append(Entry{Timestamp: "2020-04-22-15-00-00", SourcePath: "a.pdf", DestPath: "B123456.pdf", SHA256: "hash1", MTimePreserved: true}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	if exists, _ := afero.Exists(fs, "audit.log"); exists {
+		t.Fatalf("dry-run Append should not have created audit.log")
+	}
+}
+
+func TestChainDiffersWhenPrevHashDiffers(t *testing.T) {
+	entry := Entry{Timestamp: "2020-04-22-15-00-00", SourcePath: "a.pdf", DestPath: "B123456.pdf", SHA256: "hash1", MTimePreserved: true}
+
+	h1 := chain("", entry)
+	h2 := chain("some-other-prev-hash", entry)
+	if h1 == h2 {
+		t.Errorf("chain hash should depend on prevHash, got the same value for both")
+	}
+}
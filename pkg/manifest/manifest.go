@@ -0,0 +1,114 @@
+// Package manifest records a SHA-256 of every anonymised submission, so that
+// a file swapped between students (accidentally or otherwise) can be caught
+// even though each submission passes every other check on its own.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/afero"
+)
+
+// Entry is one row of manifest.csv: one anonymised output file.
+type Entry struct {
+	ExamNumber     string
+	UUN            string
+	SHA256         string
+	Bytes          int64
+	SourceFilename string
+	SubmittedAt    string
+}
+
+var header = []string{"ExamNumber", "UUN", "SHA256", "Bytes", "SourceFilename", "SubmittedAt"}
+
+// Hash streams path through SHA-256 so large PDFs don't need to be held in
+// memory, returning the hex digest and the file size in bytes.
+func Hash(fs afero.Fs, path string) (string, int64, error) {
+	f, err := fs.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), n, nil
+}
+
+// Load reads an existing manifest.csv, if one is present at path, so that
+// hash comparison spans invocations of gradex-ingest rather than resetting
+// on every run. A missing file is not an error: it returns an empty manifest.
+func Load(fs afero.Fs, path string) ([]Entry, error) {
+	f, err := fs.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	var entries []Entry
+	for _, row := range rows[1:] { // skip header
+		bytes, _ := strconv.ParseInt(row[3], 10, 64)
+		entries = append(entries, Entry{
+			ExamNumber:     row[0],
+			UUN:            row[1],
+			SHA256:         row[2],
+			Bytes:          bytes,
+			SourceFilename: row[4],
+			SubmittedAt:    row[5],
+		})
+	}
+
+	return entries, nil
+}
+
+// Write overwrites path with entries.
+func Write(fs afero.Fs, path string, entries []Entry) error {
+	f, err := fs.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		err := w.Write([]string{
+			e.ExamNumber,
+			e.UUN,
+			e.SHA256,
+			strconv.FormatInt(e.Bytes, 10),
+			e.SourceFilename,
+			e.SubmittedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Error()
+}
@@ -0,0 +1,96 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestHash(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "output.pdf", []byte("hello"), 0600)
+
+	hash, size, err := Hash(fs, "output.pdf")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if size != 5 {
+		t.Errorf("size = %d, want 5", size)
+	}
+	const wantHash = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if hash != wantHash {
+		t.Errorf("hash = %q, want %q", hash, wantHash)
+	}
+}
+
+func TestLoadMissingFileReturnsEmpty(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	entries, err := Load(fs, "manifest.csv")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if entries != nil {
+		t.Fatalf("entries = %v, want nil", entries)
+	}
+}
+
+func TestWriteLoadRoundTrip(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	want := []Entry{
+		{ExamNumber: "B123456", UUN: "s1234567", SHA256: "abc123", Bytes: 42, SourceFilename: "s1234567.pdf", SubmittedAt: "2020-04-22-15-00-00"},
+		{ExamNumber: "B765432", UUN: "s7654321", SHA256: "def456", Bytes: 99, SourceFilename: "s7654321.pdf", SubmittedAt: "2020-04-22-15-30-00"},
+	}
+
+	if err := Write(fs, "manifest.csv", want); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got, err := Load(fs, "manifest.csv")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTrackerAddFlagsCrossStudentDuplicate(t *testing.T) {
+	tr := NewTracker(nil)
+
+	tr.Add(Entry{ExamNumber: "B123456", UUN: "s1234567", SHA256: "samehash"})
+	if len(tr.Duplicates) != 0 {
+		t.Fatalf("first entry should not be flagged, got %v", tr.Duplicates)
+	}
+
+	tr.Add(Entry{ExamNumber: "B765432", UUN: "s7654321", SHA256: "samehash"})
+	if len(tr.Duplicates) != 2 {
+		t.Fatalf("got %d duplicates, want 2 (both sides flagged)", len(tr.Duplicates))
+	}
+
+	// A second submission for the same hash from an already-seen student
+	// should not be treated as a duplicate.
+	tr.Add(Entry{ExamNumber: "B123456", UUN: "s1234567", SHA256: "samehash"})
+	if len(tr.Duplicates) != 2 {
+		t.Fatalf("re-adding the same student's hash should not flag again, got %d", len(tr.Duplicates))
+	}
+
+	if len(tr.Entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(tr.Entries))
+	}
+}
+
+func TestTrackerSeededFromExistingManifest(t *testing.T) {
+	existing := []Entry{{ExamNumber: "B123456", UUN: "s1234567", SHA256: "samehash"}}
+	tr := NewTracker(existing)
+
+	tr.Add(Entry{ExamNumber: "B765432", UUN: "s7654321", SHA256: "samehash"})
+	if len(tr.Duplicates) != 2 {
+		t.Fatalf("got %d duplicates, want 2", len(tr.Duplicates))
+	}
+}
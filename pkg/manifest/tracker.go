@@ -0,0 +1,72 @@
+package manifest
+
+// Duplicate flags a submission that shares its SHA-256 with another
+// student's submission. Flagging happens here, via the manifest and its own
+// duplicates.csv, rather than as a DuplicateOf field on parselearn.Submission
+// itself: the manifest already has to persist hashes across runs to catch
+// duplicates spanning invocations, so it is the natural, single place to also
+// own the flag, instead of threading duplicate state back into every
+// Submission a run produces.
+type Duplicate struct {
+	ExamNumber            string
+	UUN                   string
+	SHA256                string
+	DuplicateOfExamNumber string
+	DuplicateOfUUN        string
+}
+
+// Tracker accumulates manifest Entries across a run and flags both sides of
+// any cross-student hash collision. Seed it with Entries read back via Load
+// so duplicate detection spans invocations, not just the current run.
+type Tracker struct {
+	Entries    []Entry
+	Duplicates []Duplicate
+
+	index   map[string]Entry
+	flagged map[string]bool
+}
+
+// NewTracker returns a Tracker seeded with a previous run's entries, keeping
+// the first entry seen for each hash.
+func NewTracker(existing []Entry) *Tracker {
+	t := &Tracker{
+		Entries: existing,
+		index:   map[string]Entry{},
+		flagged: map[string]bool{},
+	}
+	for _, e := range existing {
+		if _, ok := t.index[e.SHA256]; !ok {
+			t.index[e.SHA256] = e
+		}
+	}
+	return t
+}
+
+// Add records entry, flagging it (and, the first time, the entry it
+// collides with) in Duplicates if its hash has already been seen for a
+// different student.
+func (t *Tracker) Add(entry Entry) {
+	if original, ok := t.index[entry.SHA256]; ok && original.UUN != entry.UUN {
+		t.Duplicates = append(t.Duplicates, Duplicate{
+			ExamNumber:            entry.ExamNumber,
+			UUN:                   entry.UUN,
+			SHA256:                entry.SHA256,
+			DuplicateOfExamNumber: original.ExamNumber,
+			DuplicateOfUUN:        original.UUN,
+		})
+		if !t.flagged[entry.SHA256] {
+			t.Duplicates = append(t.Duplicates, Duplicate{
+				ExamNumber:            original.ExamNumber,
+				UUN:                   original.UUN,
+				SHA256:                entry.SHA256,
+				DuplicateOfExamNumber: entry.ExamNumber,
+				DuplicateOfUUN:        entry.UUN,
+			})
+			t.flagged[entry.SHA256] = true
+		}
+	} else if !ok {
+		t.index[entry.SHA256] = entry
+	}
+
+	t.Entries = append(t.Entries, entry)
+}
@@ -0,0 +1,168 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/emersion/go-message/mail"
+	"github.com/spf13/afero"
+)
+
+// IMAPSource fetches PDF attachments from an inbox, for students who emailed
+// their submission instead of using Learn or Forms. Messages are matched by
+// Sender and, if set, SubjectContains; the student's UUN must also appear
+// somewhere in the subject line.
+type IMAPSource struct {
+	Fs              afero.Fs
+	Server          string // host:port
+	Username        string
+	Password        string
+	Mailbox         string // e.g. "INBOX"
+	SubjectContains string
+	DownloadDir     string
+	DryRun          bool
+}
+
+func (s *IMAPSource) Name() string {
+	return "imap"
+}
+
+// Fetch opens a fresh connection, searches the mailbox for a message
+// addressed from a student whose subject contains uun, and saves its PDF
+// attachments to DownloadDir. A new connection is opened per call because
+// Fetch is only expected to run once per student per ingest.
+func (s *IMAPSource) Fetch(uun string) ([]SubmissionCandidate, error) {
+	c, err := client.DialTLS(s.Server, nil)
+	if err != nil {
+		return nil, fmt.Errorf("imap: dial %s: %w", s.Server, err)
+	}
+	defer c.Logout()
+
+	if err := c.Login(s.Username, s.Password); err != nil {
+		return nil, fmt.Errorf("imap: login: %w", err)
+	}
+
+	mailbox := s.Mailbox
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	if _, err := c.Select(mailbox, false); err != nil {
+		return nil, fmt.Errorf("imap: select %s: %w", mailbox, err)
+	}
+
+	// Header is a textproto.MIMEHeader (map[string][]string): adding both
+	// values under the same key requires the subject to match each of
+	// them, rather than one overwriting the other via Set.
+	criteria := imap.NewSearchCriteria()
+	criteria.Header.Add("Subject", uun)
+	if s.SubjectContains != "" {
+		criteria.Header.Add("Subject", s.SubjectContains)
+	}
+
+	ids, err := c.Search(criteria)
+	if err != nil {
+		return nil, fmt.Errorf("imap: search: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if s.DryRun {
+		fmt.Printf("[dryrun] would download attachments from %d email(s) for %s via IMAP\n", len(ids), uun)
+		return nil, nil
+	}
+
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(ids...)
+
+	section := &imap.BodySectionName{}
+	messages := make(chan *imap.Message, len(ids))
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Fetch(seqset, []imap.FetchItem{imap.FetchEnvelope, section.FetchItem()}, messages)
+	}()
+
+	var files []string
+	var submittedAt time.Time
+	for msg := range messages {
+		if msg.Envelope != nil && msg.Envelope.Date.After(submittedAt) {
+			submittedAt = msg.Envelope.Date
+		}
+		body := msg.GetBody(section)
+		if body == nil {
+			continue
+		}
+		attachments, err := extractPDFAttachments(s.Fs, body, s.DownloadDir, uun)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, attachments...)
+	}
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("imap: fetch: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	return []SubmissionCandidate{{
+		UUN:           uun,
+		Files:         files,
+		SubmittedAt:   submittedAt,
+		Origin:        s.Name(),
+		NumberOfFiles: len(files),
+	}}, nil
+}
+
+// extractPDFAttachments walks a MIME message body and saves any
+// application/pdf attachment parts to downloadDir, via fs.
+func extractPDFAttachments(fs afero.Fs, body io.Reader, downloadDir string, uun string) ([]string, error) {
+	mr, err := mail.CreateReader(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.MkdirAll(downloadDir, 0700); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	i := 0
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		h, ok := part.Header.(*mail.AttachmentHeader)
+		if !ok {
+			continue
+		}
+		contentType, _, err := h.ContentType()
+		if err != nil || !strings.EqualFold(contentType, "application/pdf") {
+			continue
+		}
+
+		path := filepath.Join(downloadDir, fmt.Sprintf("%s_%d.pdf", strings.ToLower(uun), i))
+		out, err := fs.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(out, part.Body)
+		out.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, path)
+		i++
+	}
+
+	return files, nil
+}
@@ -0,0 +1,130 @@
+package source
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/timdrysdale/parselearn"
+)
+
+// learnReceiptPattern extracts the UUN from a Learn-generated receipt
+// filename, e.g. "...MATH00000_s1234567_attempt_...txt".
+var learnReceiptPattern = regexp.MustCompile("_(s[0-9]{7})_attempt_")
+
+// learnAttemptTimePattern extracts the submission timestamp Learn stamps into
+// the attachment filename, e.g.
+// "...attempt_2020-04-22-15-30-00_OnlineExam-B123456.pdf". The receipt's own
+// "Date Submitted:" field is free text ("Monday, 22 April 2020 15:30:00
+// o'clock BST") with no fixed layout, so the filename is the only reliably
+// parseable source for this.
+var learnAttemptTimePattern = regexp.MustCompile(`_attempt_([0-9]{4}-[0-9]{2}-[0-9]{2}-[0-9]{2}-[0-9]{2}-[0-9]{2})_`)
+
+// LearnSource is the normal route: a classlist's worth of receipt .txt files
+// and their attachments, unzipped from a Learn export into Dir.
+type LearnSource struct {
+	Fs  afero.Fs
+	Dir string
+
+	receipts map[string]string // UUN (upper case) -> receipt filename
+}
+
+// NewLearnSource walks dir once via fs, indexing every Learn receipt by the
+// UUN embedded in its filename.
+func NewLearnSource(fs afero.Fs, dir string) (*LearnSource, error) {
+	s := &LearnSource{Fs: fs, Dir: dir, receipts: map[string]string{}}
+
+	err := afero.Walk(fs, dir, func(path string, f os.FileInfo, err error) error {
+		if err != nil || f.IsDir() {
+			return err
+		}
+		if !strings.Contains(f.Name(), ".txt") {
+			return nil
+		}
+		match := learnReceiptPattern.FindStringSubmatch(f.Name())
+		if match == nil {
+			return nil
+		}
+		s.receipts[strings.ToUpper(match[1])] = f.Name()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *LearnSource) Name() string {
+	return "learn"
+}
+
+// Fetch parses the student's Learn receipt, if one was found during the
+// initial walk, and returns its attachment as a candidate.
+func (s *LearnSource) Fetch(uun string) ([]SubmissionCandidate, error) {
+	receipt, ok := s.receipts[strings.ToUpper(uun)]
+	if !ok {
+		return nil, nil
+	}
+
+	receiptPath := filepath.Join(s.Dir, receipt)
+	submission, err := parseLearnReceipt(s.Fs, receiptPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var submittedAt time.Time
+	if match := learnAttemptTimePattern.FindStringSubmatch(submission.Filename); match != nil {
+		submittedAt, err = time.Parse("2006-01-02-15-04-05", match[1])
+		if err != nil {
+			return nil, fmt.Errorf("learn: %s: bad attempt timestamp in %q: %w", uun, submission.Filename, err)
+		}
+	}
+
+	return []SubmissionCandidate{{
+		UUN:           uun,
+		Files:         []string{filepath.Join(s.Dir, submission.Filename)},
+		SubmittedAt:   submittedAt,
+		Origin:        s.Name(),
+		ReceiptPath:   receiptPath,
+		NumberOfFiles: submission.NumberOfFiles,
+		FiletypeError: submission.FiletypeError,
+	}}, nil
+}
+
+// parseLearnReceipt reads path via fs and hands it to
+// parselearn.ParseLearnReceipt. That function only knows how to open real
+// files, so when fs isn't the real filesystem (tests, -dryrun) its contents
+// are copied out to a real temporary file first, which is removed once
+// parsing is done.
+func parseLearnReceipt(fs afero.Fs, path string) (parselearn.Submission, error) {
+	if _, ok := fs.(*afero.OsFs); ok {
+		return parselearn.ParseLearnReceipt(path)
+	}
+
+	contents, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return parselearn.Submission{}, err
+	}
+
+	tmp, err := os.CreateTemp("", "gradex-ingest-receipt-*.txt")
+	if err != nil {
+		return parselearn.Submission{}, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := tmp.Write(contents); err != nil {
+		return parselearn.Submission{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return parselearn.Submission{}, err
+	}
+
+	return parselearn.ParseLearnReceipt(tmp.Name())
+}
@@ -0,0 +1,110 @@
+package source
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// syntheticReceipt builds a Learn receipt .txt in the shape
+// parselearn.ParseLearnReceipt expects.
+func syntheticReceipt(uun, filename string) string {
+	var b strings.Builder
+	b.WriteString("Name: First Last (" + uun + ")\n")
+	b.WriteString("Assignment: Practice Exam Drop Box\n")
+	b.WriteString("Date Submitted: Monday, 22 April 2020 15:30:00 o'clock BST\n")
+	b.WriteString("Current Mark: Needs Marking\n\n")
+	b.WriteString("Submission Field:\n")
+	b.WriteString("There is no student submission text data for this assignment.\n\n")
+	b.WriteString("Comments:\n")
+	b.WriteString("There are no student comments for this assignment.\n\n")
+	b.WriteString("Files:\n")
+	b.WriteString("\tOriginal filename: OnlineExam-B123456.pdf\n")
+	b.WriteString("\tFilename: " + filename + "\n")
+	return b.String()
+}
+
+func TestLearnSourceFetch(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	const uun = "s1234567"
+	const filename = "Practice Exam Drop Box_s1234567_attempt_2020-04-22-15-30-00_OnlineExam-B123456.pdf"
+	const receiptName = "Practice Exam Drop Box_s1234567_attempt_2020-04-22-15-30-00.txt"
+
+	afero.WriteFile(fs, "learn_dir/"+receiptName, []byte(syntheticReceipt(uun, filename)), 0600)
+	afero.WriteFile(fs, "learn_dir/"+filename, []byte("scan"), 0600)
+
+	s, err := NewLearnSource(fs, "learn_dir")
+	if err != nil {
+		t.Fatalf("NewLearnSource: %v", err)
+	}
+
+	// The receipt only exists in fs, never on the real filesystem, so a
+	// non-error result here already proves Fetch went through fs rather
+	// than falling back to a real os.Open.
+	candidates, err := s.Fetch(uun)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(candidates) != 1 {
+		t.Fatalf("got %d candidates, want 1", len(candidates))
+	}
+
+	c := candidates[0]
+	if c.NumberOfFiles != 1 {
+		t.Errorf("NumberOfFiles = %d, want 1", c.NumberOfFiles)
+	}
+	if c.Origin != "learn" {
+		t.Errorf("Origin = %q, want %q", c.Origin, "learn")
+	}
+	wantFile := "learn_dir/" + filename
+	if len(c.Files) != 1 || c.Files[0] != wantFile {
+		t.Errorf("Files = %v, want [%s]", c.Files, wantFile)
+	}
+	if c.ReceiptPath != "learn_dir/"+receiptName {
+		t.Errorf("ReceiptPath = %q, want %q", c.ReceiptPath, "learn_dir/"+receiptName)
+	}
+	wantSubmittedAt := time.Date(2020, 4, 22, 15, 30, 0, 0, time.UTC)
+	if !c.SubmittedAt.Equal(wantSubmittedAt) {
+		t.Errorf("SubmittedAt = %v, want %v", c.SubmittedAt, wantSubmittedAt)
+	}
+}
+
+func TestLearnSourceFetchBadAttemptTimestamp(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	const uun = "s1234567"
+	// Matches learnAttemptTimePattern but isn't a real date (Feb 30th).
+	const filename = "Practice Exam Drop Box_s1234567_attempt_2020-02-30-15-30-00_OnlineExam-B123456.pdf"
+	const receiptName = "Practice Exam Drop Box_s1234567_attempt_2020-02-30-15-30-00.txt"
+
+	afero.WriteFile(fs, "learn_dir/"+receiptName, []byte(syntheticReceipt(uun, filename)), 0600)
+	afero.WriteFile(fs, "learn_dir/"+filename, []byte("scan"), 0600)
+
+	s, err := NewLearnSource(fs, "learn_dir")
+	if err != nil {
+		t.Fatalf("NewLearnSource: %v", err)
+	}
+
+	if _, err := s.Fetch(uun); err == nil {
+		t.Fatal("expected an error for an invalid attempt timestamp")
+	}
+}
+
+func TestLearnSourceFetchNoReceipt(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("learn_dir", 0700)
+
+	s, err := NewLearnSource(fs, "learn_dir")
+	if err != nil {
+		t.Fatalf("NewLearnSource: %v", err)
+	}
+
+	candidates, err := s.Fetch("s7654321")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if candidates != nil {
+		t.Fatalf("got %v, want nil", candidates)
+	}
+}
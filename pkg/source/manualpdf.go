@@ -0,0 +1,38 @@
+package source
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// ManualPDFSource is the last-resort route: a marker has manually replaced a
+// broken Learn submission with a single file called "uun.pdf" in Dir.
+type ManualPDFSource struct {
+	Fs  afero.Fs
+	Dir string
+}
+
+func (s *ManualPDFSource) Name() string {
+	return "manualpdf"
+}
+
+func (s *ManualPDFSource) Fetch(uun string) ([]SubmissionCandidate, error) {
+	path := filepath.Join(s.Dir, strings.ToLower(uun)+".pdf")
+
+	info, err := s.Fs.Stat(path)
+	if err != nil {
+		// No manually-created PDF for this student - not an error, just
+		// nothing for this source to offer.
+		return nil, nil
+	}
+
+	return []SubmissionCandidate{{
+		UUN:           uun,
+		Files:         []string{path},
+		SubmittedAt:   info.ModTime(),
+		Origin:        s.Name(),
+		NumberOfFiles: 1,
+	}}, nil
+}
@@ -0,0 +1,40 @@
+package source
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestManualPDFSourceFetch(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "learn_dir/s1234567.pdf", []byte("scan"), 0600)
+	s := &ManualPDFSource{Fs: fs, Dir: "learn_dir"}
+
+	candidates, err := s.Fetch("S1234567")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(candidates) != 1 {
+		t.Fatalf("got %d candidates, want 1", len(candidates))
+	}
+	c := candidates[0]
+	if c.Origin != "manualpdf" {
+		t.Errorf("Origin = %q, want %q", c.Origin, "manualpdf")
+	}
+	if len(c.Files) != 1 || c.Files[0] != "learn_dir/s1234567.pdf" {
+		t.Errorf("Files = %v, want [learn_dir/s1234567.pdf]", c.Files)
+	}
+}
+
+func TestManualPDFSourceFetchNotFound(t *testing.T) {
+	s := &ManualPDFSource{Fs: afero.NewMemMapFs(), Dir: "learn_dir"}
+
+	candidates, err := s.Fetch("s7654321")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if candidates != nil {
+		t.Fatalf("got %v, want nil", candidates)
+	}
+}
@@ -0,0 +1,185 @@
+package source
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// msFormsTimeLayouts are the "Completion time" formats seen in MS Forms CSV
+// exports: ISO with a literal "Z", and the locale short-date format Forms
+// actually uses in practice (e.g. "4/22/2020 4:00:00 PM"). Tried in order.
+var msFormsTimeLayouts = []string{
+	"2006-01-02T15:04:05Z",
+	"1/2/2006 3:04:05 PM",
+}
+
+// parseMSFormsTime tries every known "Completion time" layout in turn,
+// returning the first successful parse.
+func parseMSFormsTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range msFormsTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// msFormsResponse is one row of the Forms CSV export.
+type msFormsResponse struct {
+	submittedAt    time.Time
+	attachmentURLs []string
+}
+
+// MSFormsSource reads a CSV exported from an MS Forms file-upload question.
+// The export is expected to have "UUN", "Completion time" and "Attachment
+// URL" columns; a response with several uploaded files lists their URLs
+// separated by ";" in the one cell, as Forms does.
+type MSFormsSource struct {
+	Fs          afero.Fs
+	CSVPath     string
+	DownloadDir string
+	HTTPClient  *http.Client
+	DryRun      bool
+
+	responses map[string]msFormsResponse // UUN (upper case) -> response
+}
+
+// NewMSFormsSource reads and indexes csvPath. Downloaded attachments are
+// written under downloadDir, via fs.
+func NewMSFormsSource(fs afero.Fs, csvPath string, downloadDir string) (*MSFormsSource, error) {
+	s := &MSFormsSource{
+		Fs:          fs,
+		CSVPath:     csvPath,
+		DownloadDir: downloadDir,
+		HTTPClient:  http.DefaultClient,
+		responses:   map[string]msFormsResponse{},
+	}
+
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.TrimSpace(name)] = i
+	}
+
+	uunCol, ok := col["UUN"]
+	if !ok {
+		return nil, fmt.Errorf("msforms: no UUN column in %s", csvPath)
+	}
+	timeCol, ok := col["Completion time"]
+	if !ok {
+		return nil, fmt.Errorf("msforms: no Completion time column in %s", csvPath)
+	}
+	urlCol, ok := col["Attachment URL"]
+	if !ok {
+		return nil, fmt.Errorf("msforms: no Attachment URL column in %s", csvPath)
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		uun := strings.ToUpper(strings.TrimSpace(record[uunCol]))
+		submittedAt, err := parseMSFormsTime(record[timeCol])
+		if err != nil {
+			fmt.Printf("msforms: %s: could not parse completion time %q, treating as not late: %v\n", uun, record[timeCol], err)
+		}
+
+		var urls []string
+		for _, u := range strings.Split(record[urlCol], ";") {
+			if u = strings.TrimSpace(u); u != "" {
+				urls = append(urls, u)
+			}
+		}
+
+		s.responses[uun] = msFormsResponse{submittedAt: submittedAt, attachmentURLs: urls}
+	}
+
+	return s, nil
+}
+
+func (s *MSFormsSource) Name() string {
+	return "msforms"
+}
+
+// Fetch downloads every attachment listed for uun's response and returns
+// them as a single candidate.
+func (s *MSFormsSource) Fetch(uun string) ([]SubmissionCandidate, error) {
+	response, ok := s.responses[strings.ToUpper(uun)]
+	if !ok || len(response.attachmentURLs) == 0 {
+		return nil, nil
+	}
+
+	if s.DryRun {
+		fmt.Printf("[dryrun] would download %d attachment(s) for %s via MS Forms\n", len(response.attachmentURLs), uun)
+		return nil, nil
+	}
+
+	err := s.Fs.MkdirAll(s.DownloadDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for i, url := range response.attachmentURLs {
+		path := filepath.Join(s.DownloadDir, fmt.Sprintf("%s_%d%s", strings.ToLower(uun), i, filepath.Ext(url)))
+		if err := s.download(url, path); err != nil {
+			return nil, fmt.Errorf("msforms: downloading %s for %s: %w", url, uun, err)
+		}
+		files = append(files, path)
+	}
+
+	return []SubmissionCandidate{{
+		UUN:           uun,
+		Files:         files,
+		SubmittedAt:   response.submittedAt,
+		Origin:        s.Name(),
+		NumberOfFiles: len(files),
+	}}, nil
+}
+
+func (s *MSFormsSource) download(url string, path string) error {
+	resp, err := s.HTTPClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	out, err := s.Fs.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
@@ -0,0 +1,43 @@
+package source
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMSFormsTime(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{
+			name:  "ISO with literal Z",
+			value: "2020-04-22T16:00:00Z",
+			want:  time.Date(2020, 4, 22, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "Forms locale export",
+			value: "4/22/2020 4:00:00 PM",
+			want:  time.Date(2020, 4, 22, 16, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := parseMSFormsTime(c.value)
+			if err != nil {
+				t.Fatalf("parseMSFormsTime(%q): %v", c.value, err)
+			}
+			if !got.Equal(c.want) {
+				t.Errorf("parseMSFormsTime(%q) = %v, want %v", c.value, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseMSFormsTimeUnrecognised(t *testing.T) {
+	if _, err := parseMSFormsTime("not a time"); err == nil {
+		t.Fatal("expected an error for an unrecognised layout")
+	}
+}
@@ -0,0 +1,40 @@
+// Package source looks up student submissions from the various places they
+// can arrive: a Learn export, a manually-dropped PDF, an MS Forms CSV export,
+// or an email inbox. Each place is a Source; main queries them in priority
+// order for each student until one produces a candidate.
+package source
+
+import "time"
+
+// SubmissionCandidate is a submission found by a Source, before it has been
+// through late-detection, archive extraction or PDF merging. Files is usually
+// a single path, but archive and multi-file sources may return several.
+type SubmissionCandidate struct {
+	UUN         string
+	Files       []string
+	SubmittedAt time.Time
+	Origin      string // name of the Source that produced this candidate, e.g. "learn"
+
+	// ReceiptPath is the Learn .txt receipt associated with this candidate,
+	// if any. main removes it once the submission has been moved into place.
+	ReceiptPath string
+
+	// NumberOfFiles and FiletypeError mirror parselearn.Submission, so that
+	// the same "is this submission usable" checks apply regardless of which
+	// Source produced the candidate.
+	NumberOfFiles int
+	FiletypeError string
+}
+
+// Source finds submissions for a given student from one particular place.
+type Source interface {
+	// Name is a short, stable identifier for this source, used in logs and
+	// as SubmissionCandidate.Origin.
+	Name() string
+
+	// Fetch returns any submission candidates found for uun. It is not an
+	// error for none to be found; implementations should return a nil slice
+	// and a nil error in that case so main can fall through to the next
+	// Source in priority order.
+	Fetch(uun string) ([]SubmissionCandidate, error)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+// writeLearnReceiptFixture drops a receipt .txt and its attachment into dir,
+// in the shape source.NewLearnSource expects: the attachment timestamp is
+// what decides SubmittedAt (see pkg/source/learn.go), not the receipt's free
+// text "Date Submitted:" line.
+func writeLearnReceiptFixture(t *testing.T, dir, uun, attemptTimestamp, content string) {
+	t.Helper()
+
+	base := "Practice Exam Drop Box_" + uun + "_attempt_" + attemptTimestamp
+	attachment := base + "_scan.pdf"
+	receipt := base + ".txt"
+
+	var b strings.Builder
+	b.WriteString("Name: First Last (" + strings.ToUpper(uun) + ")\n")
+	b.WriteString("Assignment: Practice Exam Drop Box\n")
+	b.WriteString("Date Submitted: some free-text date, ignored\n")
+	b.WriteString("Current Mark: Needs Marking\n\n")
+	b.WriteString("Submission Field:\n")
+	b.WriteString("There is no student submission text data for this assignment.\n\n")
+	b.WriteString("Comments:\n")
+	b.WriteString("There are no student comments for this assignment.\n\n")
+	b.WriteString("Files:\n")
+	b.WriteString("\tOriginal filename: scan.pdf\n")
+	b.WriteString("\tFilename: " + attachment + "\n")
+
+	if err := os.WriteFile(filepath.Join(dir, receipt), []byte(b.String()), 0600); err != nil {
+		t.Fatalf("WriteFile receipt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, attachment), []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile attachment: %v", err)
+	}
+}
+
+// writeClassList writes a classlist csv with the columns run() reads:
+// UUN, ExamNumber, FirstName, LastName, ExtraTimeMinutes.
+func writeClassList(t *testing.T, path string, rows [][5]string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create classlist: %v", err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	for _, r := range rows {
+		if err := w.Write(r[:]); err != nil {
+			t.Fatalf("write classlist row: %v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		t.Fatalf("flush classlist: %v", err)
+	}
+}
+
+// TestRunEndToEnd builds a synthetic Learn export covering an on-time
+// submission, a late one, one that is late but within its extra-time
+// allowance, and two students who submitted byte-identical content, then
+// checks run() places and reports each of them correctly.
+func TestRunEndToEnd(t *testing.T) {
+	dir := t.TempDir()
+	learnDir := filepath.Join(dir, "learn_dir")
+	outputDir := filepath.Join(dir, "output_dir")
+	if err := os.MkdirAll(learnDir, 0700); err != nil {
+		t.Fatalf("MkdirAll learnDir: %v", err)
+	}
+
+	// On time: submitted well before the 16:00 deadline.
+	writeLearnReceiptFixture(t, learnDir, "s1111111", "2020-04-22-10-00-00", "ontime-content")
+	// Late: submitted two hours after the deadline, no extra time.
+	writeLearnReceiptFixture(t, learnDir, "s2222222", "2020-04-22-18-00-00", "late-content")
+	// Late by the deadline, but within a 60 minute extra-time allowance.
+	writeLearnReceiptFixture(t, learnDir, "s3333333", "2020-04-22-16-30-00", "extratime-content")
+	// Two students, byte-identical content - should be flagged as duplicates.
+	writeLearnReceiptFixture(t, learnDir, "s4444444", "2020-04-22-09-00-00", "shared-content")
+	writeLearnReceiptFixture(t, learnDir, "s5555555", "2020-04-22-09-30-00", "shared-content")
+
+	classList := filepath.Join(dir, "classlist.csv")
+	writeClassList(t, classList, [][5]string{
+		{"s1111111", "B111111", "First", "Last", "0"},
+		{"s2222222", "B222222", "First", "Last", "0"},
+		{"s3333333", "B333333", "First", "Last", "60"},
+		{"s4444444", "B444444", "First", "Last", "0"},
+		{"s5555555", "B555555", "First", "Last", "0"},
+	})
+
+	cfg := Config{
+		Course:    "MATH00000",
+		ClassList: classList,
+		LearnDir:  learnDir,
+		OutputDir: outputDir,
+		Deadline:  "2020-04-22-16-00",
+	}
+	ing := &Ingester{Fs: afero.NewOsFs()}
+
+	if err := run(cfg, ing); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	assertFile := func(name, wantContent string) {
+		t.Helper()
+		got, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Errorf("reading %s: %v", name, err)
+			return
+		}
+		if string(got) != wantContent {
+			t.Errorf("%s content = %q, want %q", name, got, wantContent)
+		}
+	}
+	assertMissing := func(name string) {
+		t.Helper()
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err == nil {
+			t.Errorf("%s should not exist", name)
+		}
+	}
+
+	assertFile("B111111.pdf", "ontime-content")
+	assertMissing("LATE-B111111.pdf")
+
+	assertFile("LATE-B222222.pdf", "late-content")
+	assertMissing("B222222.pdf")
+
+	assertFile("B333333.pdf", "extratime-content")
+	assertMissing("LATE-B333333.pdf")
+
+	assertFile("B444444.pdf", "shared-content")
+	assertFile("B555555.pdf", "shared-content")
+
+	matches, err := filepath.Glob(filepath.Join(outputDir, "*-duplicates.csv"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("Glob duplicates csv: %v, matches %v", err, matches)
+	}
+	duplicates, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatalf("reading duplicates csv: %v", err)
+	}
+	if !strings.Contains(string(duplicates), "B444444") || !strings.Contains(string(duplicates), "B555555") {
+		t.Errorf("duplicates csv = %q, want both B444444 and B555555", duplicates)
+	}
+}
@@ -9,21 +9,6 @@ import (
 	pdf "github.com/unidoc/unipdf/v3/model"
 )
 
-// pr-pal @ https://stackoverflow.com/questions/37932551/mkdir-if-not-exists-using-golang
-func ensureDir(dirName string) error {
-
-	err := os.Mkdir(dirName, 0700) //probably umasked with 22 not 02
-
-	os.Chmod(dirName, 0700)
-
-	if err == nil || os.IsExist(err) {
-		return nil
-	} else {
-		return err
-	}
-
-}
-
 func countPages(inputPath string) (int, error) {
 
 	numPages := 0